@@ -0,0 +1,158 @@
+// Package api implements the OAuth access token exchange and response parsing shared by the
+// different authorization flows.
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AccessToken represents the response from a successful OAuth access token request.
+type AccessToken struct {
+	Token        string `json:"access_token"`
+	Type         string `json:"token_type"`
+	Scope        string `json:"scope"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is the provider-reported lifetime of Token, in seconds.
+	ExpiresIn int `json:"expires_in"`
+	// Expiry is the wall-clock time Token is estimated to expire, derived from ExpiresIn at
+	// the time the token was received (or from created_at + ExpiresIn, for providers such as
+	// GoToSocial that report the token's issue time instead).
+	Expiry time.Time `json:"-"`
+}
+
+type httpClient interface {
+	PostForm(string, url.Values) (*http.Response, error)
+}
+
+// PostForm submits an HTTP POST request with the given form values and wraps the response for
+// parsing.
+func PostForm(c httpClient, rawURL string, data url.Values) (*Response, error) {
+	resp, err := c.PostForm(rawURL, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{resp}, nil
+}
+
+// Response wraps an *http.Response from the token endpoint.
+type Response struct {
+	*http.Response
+}
+
+// JSON decodes the response body as JSON into v. It is used by flows such as the device
+// authorization grant that need to read a response other than an access token.
+func (r *Response) JSON(v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// AccessToken decodes the response body into an AccessToken, returning a *TokenError if the
+// provider reports a failure instead of issuing a token.
+func (r *Response) AccessToken() (*AccessToken, error) {
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := parseBody(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if errCode := values.Get("error"); errCode != "" {
+		return nil, &TokenError{
+			StatusCode:       r.StatusCode,
+			ErrorCode:        errCode,
+			ErrorDescription: values.Get("error_description"),
+			ErrorURI:         values.Get("error_uri"),
+		}
+	}
+
+	token := &AccessToken{
+		Token:        values.Get("access_token"),
+		Type:         values.Get("token_type"),
+		Scope:        values.Get("scope"),
+		RefreshToken: values.Get("refresh_token"),
+	}
+	if token.Token == "" {
+		return nil, &TokenError{
+			StatusCode: r.StatusCode,
+			ErrorCode:  "empty_token",
+		}
+	}
+
+	if expiresIn, err := strconv.Atoi(values.Get("expires_in")); err == nil {
+		token.ExpiresIn = expiresIn
+		issuedAt := time.Now()
+		if createdAt, err := strconv.ParseInt(values.Get("created_at"), 10, 64); err == nil {
+			issuedAt = time.Unix(createdAt, 0)
+		}
+		token.Expiry = issuedAt.Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// RefreshAccessToken exchanges a refresh token for a new access token, as described in
+// RFC 6749 §6.
+func RefreshAccessToken(c httpClient, tokenURL, clientID, clientSecret, refreshToken string) (*AccessToken, error) {
+	values := url.Values{}
+	values.Set("client_id", clientID)
+	values.Set("client_secret", clientSecret)
+	values.Set("refresh_token", refreshToken)
+	values.Set("grant_type", "refresh_token")
+
+	resp, err := PostForm(c, tokenURL, values)
+	if err != nil {
+		return nil, err
+	}
+	return resp.AccessToken()
+}
+
+// parseBody decodes a token endpoint response body as JSON when contentType is
+// application/json, and otherwise as a form-encoded body for providers that predate RFC 6749's
+// JSON requirement. A body that fails to decode as JSON is reported as an error; it is not
+// retried as form-encoded.
+func parseBody(contentType string, body []byte) (url.Values, error) {
+	mimeType, _, _ := mime.ParseMediaType(contentType)
+
+	if mimeType == "application/json" {
+		var fields map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&fields); err != nil {
+			return nil, fmt.Errorf("error parsing JSON response: %w", err)
+		}
+		values := url.Values{}
+		for k, v := range fields {
+			switch v := v.(type) {
+			case json.Number:
+				values.Set(k, v.String())
+			case string:
+				values.Set(k, v)
+			default:
+				values.Set(k, fmt.Sprintf("%v", v))
+			}
+		}
+		return values, nil
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	return values, nil
+}
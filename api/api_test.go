@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newResponse(statusCode int, contentType, body string) *Response {
+	return &Response{&http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}}
+}
+
+func TestAccessToken_JSON(t *testing.T) {
+	resp := newResponse(http.StatusOK, "application/json", `{"access_token":"abc123","token_type":"bearer","scope":"repo"}`)
+
+	token, err := resp.AccessToken()
+	if err != nil {
+		t.Fatalf("AccessToken() returned error: %v", err)
+	}
+	if token.Token != "abc123" || token.Type != "bearer" || token.Scope != "repo" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+}
+
+func TestAccessToken_FormEncodedFallback(t *testing.T) {
+	resp := newResponse(http.StatusOK, "application/x-www-form-urlencoded", "access_token=abc123&token_type=bearer&scope=repo")
+
+	token, err := resp.AccessToken()
+	if err != nil {
+		t.Fatalf("AccessToken() returned error: %v", err)
+	}
+	if token.Token != "abc123" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+}
+
+func TestAccessToken_LargeUnixTimestamps(t *testing.T) {
+	// created_at is large enough (~1.7 billion) that naively stringifying a decoded
+	// float64 via fmt.Sprintf("%v", ...) switches to scientific notation and fails to
+	// parse back into an int.
+	resp := newResponse(http.StatusOK, "application/json", `{"access_token":"abc123","expires_in":7200,"created_at":1700000000}`)
+
+	token, err := resp.AccessToken()
+	if err != nil {
+		t.Fatalf("AccessToken() returned error: %v", err)
+	}
+	if token.ExpiresIn != 7200 {
+		t.Fatalf("expected ExpiresIn=7200, got %d", token.ExpiresIn)
+	}
+	wantExpiry := int64(1700000000 + 7200)
+	if token.Expiry.Unix() != wantExpiry {
+		t.Fatalf("expected Expiry unix=%d, got %d", wantExpiry, token.Expiry.Unix())
+	}
+}
+
+func TestAccessToken_LargeExpiresInWithoutCreatedAt(t *testing.T) {
+	resp := newResponse(http.StatusOK, "application/json", `{"access_token":"abc123","expires_in":31536000}`)
+
+	token, err := resp.AccessToken()
+	if err != nil {
+		t.Fatalf("AccessToken() returned error: %v", err)
+	}
+	if token.ExpiresIn != 31536000 {
+		t.Fatalf("expected ExpiresIn=31536000, got %d", token.ExpiresIn)
+	}
+}
+
+func TestAccessToken_ErrorResponse(t *testing.T) {
+	resp := newResponse(http.StatusBadRequest, "application/json", `{"error":"invalid_grant","error_description":"code expired"}`)
+
+	_, err := resp.AccessToken()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) {
+		t.Fatalf("expected *TokenError, got %T: %v", err, err)
+	}
+	if tokenErr.ErrorCode != "invalid_grant" || tokenErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected TokenError: %+v", tokenErr)
+	}
+}
@@ -0,0 +1,21 @@
+package api
+
+import "fmt"
+
+// TokenError represents an error response from the token endpoint as described in RFC 6749
+// §5.2, plus the HTTP status code of the response it was parsed from. Callers that need to
+// distinguish specific failure modes (e.g. "invalid_grant" from "access_denied") should use
+// errors.As to recover a *TokenError from the error returned by AccessToken.
+type TokenError struct {
+	StatusCode       int
+	ErrorCode        string
+	ErrorDescription string
+	ErrorURI         string
+}
+
+func (e *TokenError) Error() string {
+	if e.ErrorDescription != "" {
+		return fmt.Sprintf("oauth: %s: %s", e.ErrorCode, e.ErrorDescription)
+	}
+	return fmt.Sprintf("oauth: %s", e.ErrorCode)
+}
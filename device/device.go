@@ -0,0 +1,116 @@
+// Package device implements the OAuth 2.0 Device Authorization Grant (RFC 8628) for clients
+// that cannot start a local web server or open a browser, such as headless or SSH sessions.
+package device
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cli/oauth/api"
+)
+
+type httpClient interface {
+	PostForm(string, url.Values) (*http.Response, error)
+}
+
+// CodeResponse is the result of a device authorization request, as described in RFC 8628 §3.2.
+type CodeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// RequestCode starts the device authorization flow by requesting a device and user code from
+// deviceURL.
+func RequestCode(c httpClient, deviceURL, clientID string, scopes []string) (*CodeResponse, error) {
+	values := url.Values{}
+	values.Set("client_id", clientID)
+	if len(scopes) > 0 {
+		values.Set("scope", joinScopes(scopes))
+	}
+
+	resp, err := api.PostForm(c, deviceURL, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var code CodeResponse
+	if err := resp.JSON(&code); err != nil {
+		return nil, err
+	}
+	if code.DeviceCode == "" || code.UserCode == "" {
+		return nil, errors.New("device: no device code in response")
+	}
+	if code.Interval == 0 {
+		code.Interval = 5
+	}
+
+	return &code, nil
+}
+
+// defaultExpiresIn is used when a provider omits expires_in (or sends a non-conformant 0),
+// so Wait doesn't treat a zero-length deadline as "already expired".
+const defaultExpiresIn = 15 * time.Minute
+
+// Wait polls tokenURL at the interval given by codeResp until the user has completed
+// authorization, honoring the "authorization_pending", "slow_down", "access_denied", and
+// "expired_token" errors defined in RFC 8628 §3.5.
+func Wait(c httpClient, tokenURL, clientID string, codeResp *CodeResponse) (*api.AccessToken, error) {
+	interval := time.Duration(codeResp.Interval) * time.Second
+
+	expiresIn := time.Duration(codeResp.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = defaultExpiresIn
+	}
+	deadline := time.Now().Add(expiresIn)
+
+	values := url.Values{}
+	values.Set("client_id", clientID)
+	values.Set("device_code", codeResp.DeviceCode)
+	values.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+
+	for {
+		if time.Now().After(deadline) {
+			return nil, errors.New("device: authorization timed out")
+		}
+
+		time.Sleep(interval)
+
+		resp, err := api.PostForm(c, tokenURL, values)
+		if err != nil {
+			return nil, err
+		}
+
+		token, err := resp.AccessToken()
+		if err == nil {
+			return token, nil
+		}
+
+		var tokenErr *api.TokenError
+		if !errors.As(err, &tokenErr) {
+			return nil, err
+		}
+
+		switch tokenErr.ErrorCode {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		default:
+			return nil, tokenErr
+		}
+	}
+}
+
+func joinScopes(scopes []string) string {
+	out := scopes[0]
+	for _, s := range scopes[1:] {
+		out += " " + s
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package device
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type stubClient struct {
+	responses []*http.Response
+	posts     int
+}
+
+func (c *stubClient) PostForm(rawURL string, data url.Values) (*http.Response, error) {
+	resp := c.responses[c.posts]
+	c.posts++
+	return resp, nil
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       &readCloser{strings.NewReader(body)},
+	}
+}
+
+type readCloser struct {
+	*strings.Reader
+}
+
+func (r *readCloser) Close() error { return nil }
+
+func TestWait_ZeroExpiresInDoesNotTimeoutImmediately(t *testing.T) {
+	client := &stubClient{responses: []*http.Response{
+		jsonResponse(http.StatusOK, `{"access_token":"abc123"}`),
+	}}
+
+	codeResp := &CodeResponse{
+		DeviceCode: "devcode",
+		UserCode:   "USER-CODE",
+		Interval:   0,
+		ExpiresIn:  0,
+	}
+
+	token, err := Wait(client, "https://example.com/token", "client-id", codeResp)
+	if err != nil {
+		t.Fatalf("Wait() returned error: %v", err)
+	}
+	if token.Token != "abc123" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	if client.posts == 0 {
+		t.Fatal("expected Wait to poll the token endpoint at least once instead of timing out immediately")
+	}
+}
+
+func TestWait_AuthorizationPendingThenSuccess(t *testing.T) {
+	client := &stubClient{responses: []*http.Response{
+		jsonResponse(http.StatusBadRequest, `{"error":"authorization_pending"}`),
+		jsonResponse(http.StatusOK, `{"access_token":"abc123"}`),
+	}}
+
+	codeResp := &CodeResponse{
+		DeviceCode: "devcode",
+		UserCode:   "USER-CODE",
+		Interval:   0,
+		ExpiresIn:  60,
+	}
+
+	token, err := Wait(client, "https://example.com/token", "client-id", codeResp)
+	if err != nil {
+		t.Fatalf("Wait() returned error: %v", err)
+	}
+	if token.Token != "abc123" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	if client.posts != 2 {
+		t.Fatalf("expected 2 POSTs, got %d", client.posts)
+	}
+}
+
+func TestWait_AccessDeniedStopsImmediately(t *testing.T) {
+	client := &stubClient{responses: []*http.Response{
+		jsonResponse(http.StatusBadRequest, `{"error":"access_denied"}`),
+	}}
+
+	codeResp := &CodeResponse{
+		DeviceCode: "devcode",
+		UserCode:   "USER-CODE",
+		Interval:   0,
+		ExpiresIn:  60,
+	}
+
+	_, err := Wait(client, "https://example.com/token", "client-id", codeResp)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if client.posts != 1 {
+		t.Fatalf("expected exactly 1 POST, got %d", client.posts)
+	}
+}
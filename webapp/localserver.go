@@ -0,0 +1,104 @@
+package webapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// code is the result of the OAuth redirect callback.
+type code struct {
+	Code  string
+	State string
+}
+
+// localServer listens on a loopback port to receive the OAuth redirect from the browser.
+type localServer struct {
+	CallbackPath     string
+	WriteSuccessHTML func(io.Writer)
+
+	listener net.Listener
+	resultCh chan result
+}
+
+type result struct {
+	query url.Values
+	err   error
+}
+
+// bindLocalServer finds an available loopback port and listens on it. It returns an error if
+// no local port could be bound, which callers can use to detect headless/SSH environments
+// where no browser flow is possible.
+func bindLocalServer() (*localServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	return &localServer{
+		listener: listener,
+		resultCh: make(chan result, 1),
+	}, nil
+}
+
+// Port returns the loopback port the server is listening on.
+func (s *localServer) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Serve accepts the single OAuth redirect request and renders the success page.
+func (s *localServer) Serve() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.CallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if errCode := q.Get("error"); errCode != "" {
+			s.resultCh <- result{err: fmt.Errorf("authorization error: %s", errCode)}
+			http.Error(w, "Authorization failed. You may close this page.", http.StatusBadRequest)
+			return
+		}
+
+		s.resultCh <- result{query: q}
+
+		if s.WriteSuccessHTML != nil {
+			s.WriteSuccessHTML(w)
+		}
+	})
+
+	srv := &http.Server{Handler: mux}
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+	defer srv.Shutdown(context.Background())
+
+	select {
+	case r := <-s.resultCh:
+		s.resultCh <- r // let WaitForCode observe the same result
+		return r.err
+	case err := <-errCh:
+		return err
+	}
+}
+
+// WaitForCode blocks until Serve has received the redirect and returns the resulting code.
+func (s *localServer) WaitForCode() (code, error) {
+	q, err := s.WaitForQuery()
+	if err != nil {
+		return code{}, err
+	}
+	return code{Code: q.Get("code"), State: q.Get("state")}, nil
+}
+
+// WaitForQuery blocks until Serve has received the redirect and returns its raw query
+// parameters, for flows such as OAuth 1.0a whose callback uses different parameter names
+// (oauth_token, oauth_verifier) than the authorization code grant.
+func (s *localServer) WaitForQuery() (url.Values, error) {
+	r := <-s.resultCh
+	return r.query, r.err
+}
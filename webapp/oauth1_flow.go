@@ -0,0 +1,218 @@
+package webapp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OAuth1Flow implements the OAuth 1.0a three-legged flow with RSA-SHA1 signing, as still
+// required by Bitbucket Server. It reuses the same localhost callback server as Flow so
+// consumers get one consistent API regardless of which OAuth version a provider speaks.
+type OAuth1Flow struct {
+	server      *localServer
+	consumerKey string
+	privateKey  *rsa.PrivateKey
+
+	requestToken string
+}
+
+// InitOAuth1Flow creates a new OAuth1Flow by detecting a locally available port number. The
+// consumerKey and privateKey are issued by the provider when the application is registered.
+func InitOAuth1Flow(consumerKey string, privateKey *rsa.PrivateKey) (*OAuth1Flow, error) {
+	server, err := bindLocalServer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuth1Flow{
+		server:      server,
+		consumerKey: consumerKey,
+		privateKey:  privateKey,
+	}, nil
+}
+
+// RequestToken fetches a temporary request token from requestTokenURL, per RFC 5849 §2.1.
+func (flow *OAuth1Flow) RequestToken(c httpClient, requestTokenURL, redirectURI string) error {
+	ru, err := url.Parse(redirectURI)
+	if err != nil {
+		return err
+	}
+	ru.Host = fmt.Sprintf("%s:%d", ru.Hostname(), flow.server.Port())
+	flow.server.CallbackPath = ru.Path
+
+	params := map[string]string{"oauth_callback": ru.String()}
+	resp, err := flow.signedPostForm(c, requestTokenURL, params)
+	if err != nil {
+		return err
+	}
+
+	values, err := readFormBody(resp)
+	if err != nil {
+		return err
+	}
+	if values.Get("oauth_callback_confirmed") != "true" {
+		return errors.New("oauth1: provider did not confirm callback")
+	}
+
+	flow.requestToken = values.Get("oauth_token")
+	if flow.requestToken == "" {
+		return errors.New("oauth1: no request token in response")
+	}
+	return nil
+}
+
+// BrowserURL returns the URL the user should visit to authorize the application, using the
+// request token obtained from RequestToken.
+func (flow *OAuth1Flow) BrowserURL(authorizeURL string) string {
+	q := url.Values{}
+	q.Set("oauth_token", flow.requestToken)
+	return fmt.Sprintf("%s?%s", authorizeURL, q.Encode())
+}
+
+// StartServer starts the localhost server and blocks until it has received the authorization
+// callback. The writeSuccess function can be used to render a HTML page to the user upon
+// completion, mirroring Flow.StartServer.
+func (flow *OAuth1Flow) StartServer(writeSuccess func(io.Writer)) error {
+	flow.server.WriteSuccessHTML = writeSuccess
+	return flow.server.Serve()
+}
+
+// AccessToken exchanges the verifier received from the authorization callback for a
+// long-lived access token and secret, per RFC 5849 §2.3.
+func (flow *OAuth1Flow) AccessToken(c httpClient, accessTokenURL string) (token, secret string, err error) {
+	query, err := flow.server.WaitForQuery()
+	if err != nil {
+		return "", "", err
+	}
+
+	oauthToken := query.Get("oauth_token")
+	verifier := query.Get("oauth_verifier")
+	if oauthToken != flow.requestToken {
+		return "", "", errors.New("oauth1: token mismatch")
+	}
+
+	params := map[string]string{
+		"oauth_token":    oauthToken,
+		"oauth_verifier": verifier,
+	}
+	resp, err := flow.signedPostForm(c, accessTokenURL, params)
+	if err != nil {
+		return "", "", err
+	}
+
+	values, err := readFormBody(resp)
+	if err != nil {
+		return "", "", err
+	}
+
+	token = values.Get("oauth_token")
+	secret = values.Get("oauth_token_secret")
+	if token == "" {
+		return "", "", errors.New("oauth1: no access token in response")
+	}
+	return token, secret, nil
+}
+
+// signedPostForm signs params per OAuth 1.0a's RSA-SHA1 method (RFC 5849 §3.4) and POSTs them
+// to rawURL. Unlike HMAC-SHA1, RSA-SHA1 signs with the consumer's RSA private key alone (RFC
+// 5849 §3.4.3); there is no token secret folded into the signing key, so callers don't need to
+// supply one.
+func (flow *OAuth1Flow) signedPostForm(c httpClient, rawURL string, params map[string]string) (*http.Response, error) {
+	nonce, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     flow.consumerKey,
+		"oauth_nonce":            nonce,
+		"oauth_signature_method": "RSA-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_version":          "1.0",
+	}
+	for k, v := range params {
+		oauthParams[k] = v
+	}
+
+	signature, err := flow.sign("POST", rawURL, oauthParams)
+	if err != nil {
+		return nil, err
+	}
+	oauthParams["oauth_signature"] = signature
+
+	form := url.Values{}
+	for k, v := range oauthParams {
+		form.Set(k, v)
+	}
+
+	return c.PostForm(rawURL, form)
+}
+
+// sign computes the RSA-SHA1 signature for an OAuth 1.0a request as described in RFC 5849
+// §3.4.1 and §3.4.3.
+func (flow *OAuth1Flow) sign(method, rawURL string, params map[string]string) (string, error) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", rfc3986Escape(k), rfc3986Escape(params[k]))
+	}
+
+	base := strings.Join([]string{
+		method,
+		rfc3986Escape(rawURL),
+		rfc3986Escape(strings.Join(pairs, "&")),
+	}, "&")
+
+	h := sha1.Sum([]byte(base))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, flow.privateKey, crypto.SHA1, h[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// rfc3986Unreserved is the "unreserved" character set of RFC 3986 §2.3, the only characters
+// OAuth 1.0a's percent-encoding (RFC 5849 §3.6) leaves unescaped. Notably this differs from
+// url.QueryEscape, which is application/x-www-form-urlencoded and escapes space as "+".
+const rfc3986Unreserved = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+
+// rfc3986Escape percent-encodes s per RFC 3986 §2.1, as required for OAuth 1.0a signature base
+// strings.
+func rfc3986Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if strings.IndexByte(rfc3986Unreserved, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func readFormBody(resp *http.Response) (url.Values, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseQuery(string(body))
+}
@@ -0,0 +1,91 @@
+package webapp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestRandomPKCEVerifier(t *testing.T) {
+	verifier, err := randomPKCEVerifier()
+	if err != nil {
+		t.Fatalf("randomPKCEVerifier() returned error: %v", err)
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Fatalf("verifier length %d outside RFC 7636 bounds [43,128]", len(verifier))
+	}
+	for _, r := range verifier {
+		if !strings.ContainsRune(pkceVerifierCharset, r) {
+			t.Fatalf("verifier contains disallowed character %q", r)
+		}
+	}
+}
+
+func TestCodeChallengeS256(t *testing.T) {
+	verifier := "abc123"
+	got := codeChallengeS256(verifier)
+
+	sum := sha256.Sum256([]byte(verifier))
+	want := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	if got != want {
+		t.Fatalf("codeChallengeS256(%q) = %q, want %q", verifier, got, want)
+	}
+	if strings.Contains(got, "=") {
+		t.Fatalf("codeChallengeS256 result contains padding: %q", got)
+	}
+}
+
+func TestBrowserURL_PKCEGatesCodeVerifier(t *testing.T) {
+	server, err := bindLocalServer()
+	if err != nil {
+		t.Fatalf("bindLocalServer() returned error: %v", err)
+	}
+	flow := &Flow{server: server, codeVerifier: "verifier-value"}
+
+	if _, err := flow.BrowserURL("https://example.com/authorize", BrowserParams{
+		ClientID:    "client",
+		RedirectURI: "http://127.0.0.1/callback",
+	}); err != nil {
+		t.Fatalf("BrowserURL() without PKCE returned error: %v", err)
+	}
+	if flow.pkceEnabled {
+		t.Fatal("pkceEnabled should be false when BrowserParams.PKCE is false")
+	}
+
+	if _, err := flow.BrowserURL("https://example.com/authorize", BrowserParams{
+		ClientID:    "client",
+		RedirectURI: "http://127.0.0.1/callback",
+		PKCE:        true,
+	}); err != nil {
+		t.Fatalf("BrowserURL() with PKCE returned error: %v", err)
+	}
+	if !flow.pkceEnabled {
+		t.Fatal("pkceEnabled should be true when BrowserParams.PKCE is true")
+	}
+}
+
+func TestBrowserURL_PKCEPlainMethod(t *testing.T) {
+	server, err := bindLocalServer()
+	if err != nil {
+		t.Fatalf("bindLocalServer() returned error: %v", err)
+	}
+	flow := &Flow{server: server, codeVerifier: "verifier-value"}
+
+	rawURL, err := flow.BrowserURL("https://example.com/authorize", BrowserParams{
+		ClientID:    "client",
+		RedirectURI: "http://127.0.0.1/callback",
+		PKCE:        true,
+		PKCEMethod:  PKCEMethodPlain,
+	})
+	if err != nil {
+		t.Fatalf("BrowserURL() returned error: %v", err)
+	}
+	if !strings.Contains(rawURL, "code_challenge=verifier-value") {
+		t.Fatalf("expected plain code_challenge to equal the verifier, got %q", rawURL)
+	}
+	if !strings.Contains(rawURL, "code_challenge_method=plain") {
+		t.Fatalf("expected code_challenge_method=plain, got %q", rawURL)
+	}
+}
@@ -0,0 +1,54 @@
+// Package providers holds ready-made OAuth endpoint configurations for common providers, so
+// callers don't have to hand-assemble authorize/token URLs and default scopes themselves.
+package providers
+
+// Provider describes the endpoints and default scopes for an OAuth provider.
+type Provider struct {
+	AuthorizeURL  string
+	TokenURL      string
+	DeviceURL     string
+	DefaultScopes []string
+}
+
+// GitHub is the endpoint configuration for github.com.
+var GitHub = Provider{
+	AuthorizeURL:  "https://github.com/login/oauth/authorize",
+	TokenURL:      "https://github.com/login/oauth/access_token",
+	DeviceURL:     "https://github.com/login/device/code",
+	DefaultScopes: []string{"repo", "read:org"},
+}
+
+// GitHubEnterprise returns the endpoint configuration for a GitHub Enterprise Server instance
+// at the given base URL, e.g. "https://github.example.com".
+func GitHubEnterprise(baseURL string) Provider {
+	return Provider{
+		AuthorizeURL:  baseURL + "/login/oauth/authorize",
+		TokenURL:      baseURL + "/login/oauth/access_token",
+		DeviceURL:     baseURL + "/login/device/code",
+		DefaultScopes: []string{"repo", "read:org"},
+	}
+}
+
+// GitLab is the endpoint configuration for gitlab.com.
+var GitLab = Provider{
+	AuthorizeURL:  "https://gitlab.com/oauth/authorize",
+	TokenURL:      "https://gitlab.com/oauth/token",
+	DeviceURL:     "https://gitlab.com/oauth/authorize_device",
+	DefaultScopes: []string{"read_user", "api"},
+}
+
+// Google is the endpoint configuration for Google's OAuth 2.0 API.
+var Google = Provider{
+	AuthorizeURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+	TokenURL:      "https://oauth2.googleapis.com/token",
+	DeviceURL:     "https://oauth2.googleapis.com/device/code",
+	DefaultScopes: []string{"openid", "email", "profile"},
+}
+
+// BitbucketCloud is the endpoint configuration for bitbucket.org. Bitbucket Server, by
+// contrast, only supports OAuth 1.0a; see webapp.OAuth1Flow for that flow.
+var BitbucketCloud = Provider{
+	AuthorizeURL:  "https://bitbucket.org/site/oauth2/authorize",
+	TokenURL:      "https://bitbucket.org/site/oauth2/access_token",
+	DefaultScopes: []string{"account", "repository"},
+}
@@ -0,0 +1,121 @@
+package webapp
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cli/oauth/api"
+)
+
+// ErrNoToken is returned by TokenSource.Token when the Store has no token saved yet, meaning
+// the caller needs to run an authorization flow before a token is available.
+var ErrNoToken = errors.New("webapp: no token stored; run the authorization flow first")
+
+// TokenStore loads and saves an access token between process invocations, so that callers such
+// as CLIs don't force the user to re-authenticate on every run.
+type TokenStore interface {
+	Load() (*api.AccessToken, error)
+	Save(*api.AccessToken) error
+}
+
+// defaultRefreshSkew is how far ahead of a token's reported expiry TokenSource will proactively
+// refresh it.
+const defaultRefreshSkew = 5 * time.Minute
+
+// TokenSource vends a valid access token, transparently refreshing it via the token endpoint
+// when it is within RefreshSkew of expiring. It is analogous to golang.org/x/oauth2.TokenSource,
+// but persists the refreshed token to a TokenStore.
+type TokenSource struct {
+	Client       httpClient
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Store        TokenStore
+	// RefreshSkew is how far ahead of expiry to refresh. Defaults to 5 minutes.
+	RefreshSkew time.Duration
+
+	mu    sync.Mutex
+	token *api.AccessToken
+}
+
+// Token returns the access token, loading it from the Store on first use and refreshing it if
+// it is expired or within RefreshSkew of expiring. If the Store holds no token, Token returns
+// ErrNoToken. A token with no RefreshToken or no known Expiry is returned as is, even if it has
+// expired.
+func (ts *TokenSource) Token() (*api.AccessToken, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token == nil {
+		token, err := ts.Store.Load()
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, ErrNoToken
+		}
+		ts.token = token
+	}
+
+	if ts.needsRefresh() {
+		token, err := api.RefreshAccessToken(ts.Client, ts.TokenURL, ts.ClientID, ts.ClientSecret, ts.token.RefreshToken)
+		if err != nil {
+			return nil, err
+		}
+		if err := ts.Store.Save(token); err != nil {
+			return nil, err
+		}
+		ts.token = token
+	}
+
+	return ts.token, nil
+}
+
+func (ts *TokenSource) needsRefresh() bool {
+	if ts.token.Expiry.IsZero() || ts.token.RefreshToken == "" {
+		return false
+	}
+	skew := ts.RefreshSkew
+	if skew == 0 {
+		skew = defaultRefreshSkew
+	}
+	return time.Now().Add(skew).After(ts.token.Expiry)
+}
+
+// FileTokenStore is a TokenStore backed by a gob-encoded file on disk, created with 0600
+// permissions so only the owner can read the stored token.
+type FileTokenStore struct {
+	Path string
+}
+
+// Load reads the access token from Path. It returns (nil, nil) if the file does not exist yet.
+func (s *FileTokenStore) Load() (*api.AccessToken, error) {
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var token api.AccessToken
+	if err := gob.NewDecoder(f).Decode(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Save writes token to Path, replacing any existing file.
+func (s *FileTokenStore) Save(token *api.AccessToken) error {
+	f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gob.NewEncoder(f).Encode(token)
+}
@@ -0,0 +1,149 @@
+package webapp
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cli/oauth/api"
+)
+
+type stubHTTPClient struct {
+	response *http.Response
+}
+
+func (c *stubHTTPClient) PostForm(rawURL string, data url.Values) (*http.Response, error) {
+	return c.response, nil
+}
+
+func jsonHTTPResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+type memTokenStore struct {
+	token *api.AccessToken
+	saves int
+}
+
+func (s *memTokenStore) Load() (*api.AccessToken, error) { return s.token, nil }
+func (s *memTokenStore) Save(t *api.AccessToken) error {
+	s.token = t
+	s.saves++
+	return nil
+}
+
+func TestTokenSource_NoStoredTokenReturnsErrNoToken(t *testing.T) {
+	ts := &TokenSource{Store: &memTokenStore{}}
+
+	_, err := ts.Token()
+	if err != ErrNoToken {
+		t.Fatalf("expected ErrNoToken, got %v", err)
+	}
+}
+
+func TestTokenSource_NotExpiredDoesNotRefresh(t *testing.T) {
+	store := &memTokenStore{token: &api.AccessToken{
+		Token:        "still-good",
+		RefreshToken: "refresh-me",
+		Expiry:       time.Now().Add(time.Hour),
+	}}
+	ts := &TokenSource{Store: store}
+
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() returned error: %v", err)
+	}
+	if token.Token != "still-good" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	if store.saves != 0 {
+		t.Fatalf("expected no refresh/save, got %d saves", store.saves)
+	}
+}
+
+func TestTokenSource_WithinSkewRefreshesAndSaves(t *testing.T) {
+	store := &memTokenStore{token: &api.AccessToken{
+		Token:        "about-to-expire",
+		RefreshToken: "refresh-me",
+		Expiry:       time.Now().Add(time.Minute), // inside the default 5-minute skew
+	}}
+	client := &stubHTTPClient{response: jsonHTTPResponse(`{"access_token":"refreshed","refresh_token":"refresh-me"}`)}
+	ts := &TokenSource{Store: store, Client: client}
+
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() returned error: %v", err)
+	}
+	if token.Token != "refreshed" {
+		t.Fatalf("expected refreshed token, got %+v", token)
+	}
+	if store.saves != 1 {
+		t.Fatalf("expected 1 save, got %d", store.saves)
+	}
+}
+
+func TestTokenSource_NoRefreshTokenNeverRefreshes(t *testing.T) {
+	store := &memTokenStore{token: &api.AccessToken{
+		Token:  "no-refresh-available",
+		Expiry: time.Now().Add(-time.Hour), // already expired, but nothing to refresh with
+	}}
+	ts := &TokenSource{Store: store}
+
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Token() returned error: %v", err)
+	}
+	if token.Token != "no-refresh-available" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	if store.saves != 0 {
+		t.Fatalf("expected no save, got %d", store.saves)
+	}
+}
+
+func TestFileTokenStore_LoadMissingFileReturnsNil(t *testing.T) {
+	store := &FileTokenStore{Path: filepath.Join(t.TempDir(), "does-not-exist")}
+
+	token, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if token != nil {
+		t.Fatalf("expected nil token, got %+v", token)
+	}
+}
+
+func TestFileTokenStore_SaveThenLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.gob")
+	store := &FileTokenStore{Path: path}
+
+	want := &api.AccessToken{Token: "abc123", RefreshToken: "refresh-me", Scope: "repo"}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat() returned error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Fatalf("expected file perms 0600, got %o", perm)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got.Token != want.Token || got.RefreshToken != want.RefreshToken || got.Scope != want.Scope {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", got, want)
+	}
+}
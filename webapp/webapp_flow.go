@@ -4,6 +4,8 @@ package webapp
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -13,17 +15,36 @@ import (
 	"strings"
 
 	"github.com/cli/oauth/api"
+	"github.com/cli/oauth/device"
 )
 
+// pkceVerifierCharset is the set of "unreserved" characters allowed in a PKCE code verifier
+// per RFC 7636 §4.1.
+const pkceVerifierCharset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+
+// pkceVerifierLength is the length of the generated code verifier. RFC 7636 allows 43-128
+// characters; 96 gives comfortable entropy while staying well under the limit.
+const pkceVerifierLength = 96
+
 type httpClient interface {
 	PostForm(string, url.Values) (*http.Response, error)
 }
 
 // Flow holds the state for the steps of OAuth Web Application flow.
 type Flow struct {
-	server   *localServer
-	clientID string
-	state    string
+	server       *localServer
+	clientID     string
+	state        string
+	codeVerifier string
+
+	// pkceEnabled is set by BrowserURL when params.PKCE is true, so that
+	// AccessTokenWithParams only sends code_verifier for flows that actually sent a
+	// code_challenge.
+	pkceEnabled bool
+
+	// deviceCode is set when the flow has fallen back to the device authorization grant
+	// because no local server could be bound.
+	deviceCode *device.CodeResponse
 }
 
 // InitFlow creates a new Flow instance by detecting a locally available port number.
@@ -34,10 +55,47 @@ func InitFlow() (*Flow, error) {
 	}
 
 	state, _ := randomString(20)
+	codeVerifier, err := randomPKCEVerifier()
+	if err != nil {
+		return nil, err
+	}
 
 	return &Flow{
-		server: server,
-		state:  state,
+		server:       server,
+		state:        state,
+		codeVerifier: codeVerifier,
+	}, nil
+}
+
+// CodeVerifier returns the PKCE code verifier generated for this flow, for callers that want
+// to drive the token exchange themselves instead of using AccessTokenWithParams.
+func (flow *Flow) CodeVerifier() string {
+	return flow.codeVerifier
+}
+
+// InitFlowWithDeviceFallback behaves like InitFlow, but falls back to the OAuth device
+// authorization grant (RFC 8628) when no local server could be bound, which typically means
+// there is no browser available to receive the redirect (e.g. headless or SSH sessions). On
+// fallback, printCode is called with the user code and verification URL to show the user, and
+// the returned Flow's AccessTokenWithParams polls the token endpoint instead of waiting for a
+// browser redirect.
+func InitFlowWithDeviceFallback(c httpClient, deviceURL, clientID string, scopes []string, printCode func(userCode, verificationURI string)) (*Flow, error) {
+	flow, err := InitFlow()
+	if err == nil {
+		return flow, nil
+	}
+
+	codeResp, codeErr := device.RequestCode(c, deviceURL, clientID, scopes)
+	if codeErr != nil {
+		return nil, codeErr
+	}
+	printCode(codeResp.UserCode, codeResp.VerificationURI)
+
+	state, _ := randomString(20)
+	return &Flow{
+		clientID:   clientID,
+		state:      state,
+		deviceCode: codeResp,
 	}, nil
 }
 
@@ -48,8 +106,20 @@ type BrowserParams struct {
 	Scopes      []string
 	LoginHandle string
 	AllowSignup bool
+	// PKCE adds a code_challenge derived from the flow's code verifier to the authorization
+	// request, as required by public clients and recommended for all clients by RFC 7636.
+	PKCE bool
+	// PKCEMethod selects the code_challenge_method: "S256" (the default, used when left
+	// empty) or "plain". Only meaningful when PKCE is true.
+	PKCEMethod string
 }
 
+// PKCE code_challenge_method values, per RFC 7636 §4.3.
+const (
+	PKCEMethodS256  = "S256"
+	PKCEMethodPlain = "plain"
+)
+
 // BrowserURL appends GET query parameters to baseURL and returns the url that the user should
 // navigate to in their web browser.
 func (flow *Flow) BrowserURL(baseURL string, params BrowserParams) (string, error) {
@@ -73,6 +143,22 @@ func (flow *Flow) BrowserURL(baseURL string, params BrowserParams) (string, erro
 	if !params.AllowSignup {
 		q.Set("allow_signup", "false")
 	}
+	if params.PKCE {
+		method := params.PKCEMethod
+		if method == "" {
+			method = PKCEMethodS256
+		}
+
+		challenge := flow.codeVerifier
+		if method == PKCEMethodS256 {
+			challenge = codeChallengeS256(flow.codeVerifier)
+		}
+
+		q.Set("code_challenge", challenge)
+		q.Set("code_challenge_method", method)
+
+		flow.pkceEnabled = true
+	}
 
 	return fmt.Sprintf("%s?%s", baseURL, q.Encode()), nil
 }
@@ -85,6 +171,10 @@ func (flow *Flow) StartServer(writeSuccess func(io.Writer)) error {
 }
 
 func (flow *Flow) AccessTokenWithParams(c httpClient, tokenURL, clientSecret string, postParams url.Values) (*api.AccessToken, error) {
+	if flow.deviceCode != nil {
+		return device.Wait(c, tokenURL, flow.clientID, flow.deviceCode)
+	}
+
 	code, err := flow.server.WaitForCode()
 	if err != nil {
 		return nil, err
@@ -101,6 +191,9 @@ func (flow *Flow) AccessTokenWithParams(c httpClient, tokenURL, clientSecret str
 	postParams["client_secret"] = []string{clientSecret}
 	postParams["code"] = []string{code.Code}
 	postParams["state"] = []string{flow.state}
+	if flow.pkceEnabled {
+		postParams["code_verifier"] = []string{flow.codeVerifier}
+	}
 
 	resp, err := api.PostForm(c, tokenURL, postParams)
 	if err != nil {
@@ -123,3 +216,25 @@ func randomString(length int) (string, error) {
 	}
 	return hex.EncodeToString(b), nil
 }
+
+// randomPKCEVerifier generates a PKCE code verifier: a cryptographically random string drawn
+// from the unreserved character set of RFC 7636 §4.1.
+func randomPKCEVerifier() (string, error) {
+	b := make([]byte, pkceVerifierLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	out := make([]byte, pkceVerifierLength)
+	for i, v := range b {
+		out[i] = pkceVerifierCharset[int(v)%len(pkceVerifierCharset)]
+	}
+	return string(out), nil
+}
+
+// codeChallengeS256 derives the PKCE code_challenge for the "S256" method: the base64url
+// (no padding) encoding of the SHA-256 hash of the verifier.
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}